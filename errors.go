@@ -0,0 +1,148 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// permanentError marks an error as one that a Retryer should not retry.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Retryer.Run gives up immediately and returns
+// err, instead of retrying it. It's meant for classifying an error from
+// within fn itself, e.g. a 401 response that retrying will never fix:
+//
+//	err := r.Run(ctx, func() error {
+//		resp, err := http.Get(url)
+//		if err != nil {
+//			return err
+//		}
+//		if resp.StatusCode == http.StatusUnauthorized {
+//			return retry.Permanent(errUnauthorized)
+//		}
+//		...
+//	})
+//
+// Permanent returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// AttemptError records a single failed attempt from one of the package's
+// retry loops: which attempt it was, how much time had elapsed since the
+// loop started, and the error that attempt returned.
+type AttemptError struct {
+	Attempt int
+	Elapsed time.Duration
+	Err     error
+}
+
+func (e *AttemptError) Error() string {
+	return fmt.Sprintf("attempt %d after %s: %s", e.Attempt, e.Elapsed, e.Err)
+}
+
+func (e *AttemptError) Unwrap() error { return e.Err }
+
+// multiError aggregates the AttemptErrors from a single retry loop, in the
+// order they occurred, so callers can see how a failure evolved over time
+// (e.g. DNS errors giving way to TLS errors giving way to 503s) instead of
+// only the final one.
+type multiError struct {
+	errs []error // each is *AttemptError
+}
+
+func (m *multiError) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d attempts failed:", len(m.errs))
+	for _, err := range m.errs {
+		fmt.Fprintf(&b, "\n\t%s", err)
+	}
+	return b.String()
+}
+
+func (m *multiError) Unwrap() []error { return m.errs }
+
+func aggregateErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &multiError{errs: errs}
+}
+
+// aggregateOrDone is like aggregateErrors, but for a loop that gives up
+// because ctx is done: if no attempts were recorded yet, it returns
+// ctx.Err() instead of nil, so a ctx that's already done before the first
+// attempt still reports a failure.
+func aggregateOrDone(ctx context.Context, errs []error) error {
+	if len(errs) == 0 {
+		return ctx.Err()
+	}
+	return aggregateErrors(errs)
+}
+
+// AttemptErrors extracts the per-attempt errors recorded by Attempts,
+// Timeout, Backoff, BackoffContext, or a Retryer, in attempt order. It
+// returns nil if err is nil or wasn't produced by one of those exhausting
+// its attempts.
+func AttemptErrors(err error) []AttemptError {
+	var m *multiError
+	if !errors.As(err, &m) {
+		return nil
+	}
+	out := make([]AttemptError, len(m.errs))
+	for i, e := range m.errs {
+		out[i] = *e.(*AttemptError)
+	}
+	return out
+}
+
+// classify is shared by every retry loop in the package to decide whether
+// err should end the loop immediately: a Permanent error always does, and
+// any other error does if isRetryable (defaultIsRetryable when nil)
+// rejects it. stop reports whether the loop should give up, and give is
+// the error it should return in that case.
+func classify(err error, isRetryable func(err error) bool) (give error, stop bool) {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return perm.err, true
+	}
+
+	if isRetryable == nil {
+		isRetryable = defaultIsRetryable
+	}
+	if !isRetryable(err) {
+		return err, true
+	}
+
+	return nil, false
+}
+
+// defaultIsRetryable is used when IsRetryable is nil: context cancellation
+// and errors that explicitly report themselves as non-temporary are not
+// retried.
+func defaultIsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var temp interface{ Temporary() bool }
+	if errors.As(err, &temp) && !temp.Temporary() {
+		return false
+	}
+
+	return true
+}
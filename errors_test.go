@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermanent(t *testing.T) {
+	t.Run("stops the retry loop", func(t *testing.T) {
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			if count == 3 {
+				return Permanent(io.ErrUnexpectedEOF)
+			}
+			return io.EOF
+		})
+		assert.Equal(t, 3, count)
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+
+	t.Run("nil in, nil out", func(t *testing.T) {
+		assert.NoError(t, Permanent(nil))
+	})
+}
+
+func TestRetryerIsRetryable(t *testing.T) {
+	t.Run("custom classifier gives up immediately", func(t *testing.T) {
+		expectedErr := errors.New("no such file")
+		r := &Retryer{
+			Floor:       time.Millisecond,
+			Ceil:        time.Millisecond,
+			IsRetryable: func(err error) bool { return false },
+		}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			return expectedErr
+		})
+		assert.Equal(t, 1, count)
+		assert.Equal(t, expectedErr, err)
+	})
+
+	t.Run("default classifier stops on non-temporary net errors", func(t *testing.T) {
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			return &testNetError{temporary: false}
+		})
+		assert.Equal(t, 1, count)
+		require.Error(t, err)
+	})
+
+	t.Run("default classifier stops on context cancellation", func(t *testing.T) {
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			return context.Canceled
+		})
+		assert.Equal(t, 1, count)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("aggregates every attempt when the deadline runs out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+		defer cancel()
+
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		err := r.Run(ctx, func() error {
+			return io.EOF
+		})
+		require.Error(t, err)
+
+		attempts := AttemptErrors(err)
+		require.NotEmpty(t, attempts)
+		for i, a := range attempts {
+			assert.Equal(t, i+1, a.Attempt)
+			assert.ErrorIs(t, a.Err, io.EOF)
+		}
+	})
+
+	t.Run("AttemptErrors returns nil for unrelated errors", func(t *testing.T) {
+		assert.Nil(t, AttemptErrors(io.EOF))
+	})
+}
@@ -0,0 +1,124 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// Group deduplicates retried operations by key, similar in spirit to
+// golang.org/x/sync/singleflight but retry-aware: if N goroutines call Do
+// with the same key while a retry loop for that key is already in flight,
+// they all share the one attempt instead of each starting their own
+// backoff against the same failing dependency.
+type Group struct {
+	mu sync.Mutex
+	m  map[string]*call
+}
+
+// call tracks one in-flight (or just-finished) Do for a given key.
+type call struct {
+	mu      sync.Mutex
+	waiters int
+	torn    bool // true once the call is no longer joinable
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	val any
+	err error
+}
+
+func newCall() *call {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &call{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+}
+
+// join attaches waiterCtx to c, returning false if c is already torn down
+// and shouldn't be joined (the caller should start a fresh call instead).
+func (c *call) join(waiterCtx context.Context) bool {
+	c.mu.Lock()
+	if c.torn {
+		c.mu.Unlock()
+		return false
+	}
+	c.waiters++
+	c.mu.Unlock()
+
+	go c.watch(waiterCtx)
+	return true
+}
+
+// watch cancels c's shared context once every waiter that joined has had
+// its own context cancelled, so the retry loop isn't kept alive on behalf
+// of callers who are no longer listening.
+func (c *call) watch(waiterCtx context.Context) {
+	select {
+	case <-waiterCtx.Done():
+		c.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.torn = true
+			c.cancel()
+		}
+		c.mu.Unlock()
+	case <-c.done:
+	}
+}
+
+// Do calls fn and retries it with retryer until it succeeds, is given up
+// on, or every caller sharing this key has had its own ctx cancelled. If
+// another goroutine is already retrying the same key, Do attaches to that
+// attempt instead of starting a new one, and shared reports whether that
+// happened.
+//
+// The context passed to fn is derived from every joined caller's ctx: it's
+// cancelled only once all of them are done, not when any individual one
+// is. If all callers abandon a key while a retry loop is still in flight,
+// that loop's context is cancelled and it gives up; should a new caller
+// arrive before that teardown completes, it starts a fresh attempt rather
+// than inheriting the abandoned one's cancellation error.
+func (g *Group) Do(ctx context.Context, key string, retryer Retryer, fn func(ctx context.Context) (any, error)) (val any, shared bool, err error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+
+	c, ok := g.m[key]
+	if ok && c.join(ctx) {
+		shared = true
+	} else {
+		c = newCall()
+		c.join(ctx)
+		g.m[key] = c
+		go g.execute(c, key, retryer, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, shared, c.err
+	case <-ctx.Done():
+		return nil, shared, ctx.Err()
+	}
+}
+
+// execute runs the retry loop for c and publishes its result, then removes
+// c from the group so the next caller for key starts a fresh call.
+func (g *Group) execute(c *call, key string, retryer Retryer, fn func(ctx context.Context) (any, error)) {
+	c.err = retryer.Run(c.ctx, func() error {
+		v, err := fn(c.ctx)
+		if err == nil {
+			c.val = v
+		}
+		return err
+	})
+
+	g.mu.Lock()
+	if g.m[key] == c {
+		delete(g.m, key)
+	}
+	g.mu.Unlock()
+
+	close(c.done)
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupDo(t *testing.T) {
+	t.Run("concurrent callers share one attempt", func(t *testing.T) {
+		var g Group
+		var calls int32
+
+		retryer := Retryer{Floor: time.Millisecond, Ceil: time.Millisecond * 10}
+
+		const n = 10
+		var wg sync.WaitGroup
+		results := make([]any, n)
+		shareds := make([]bool, n)
+		errs := make([]error, n)
+
+		var ready sync.WaitGroup
+		ready.Add(n)
+
+		for i := 0; i < n; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ready.Done()
+				ready.Wait()
+
+				val, shared, err := g.Do(context.Background(), "key", retryer, func(ctx context.Context) (any, error) {
+					atomic.AddInt32(&calls, 1)
+					time.Sleep(time.Millisecond * 20)
+					return "result", nil
+				})
+				results[i] = val
+				shareds[i] = shared
+				errs[i] = err
+			}()
+		}
+		wg.Wait()
+
+		assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+		var sharedCount int
+		for i := 0; i < n; i++ {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, "result", results[i])
+			if shareds[i] {
+				sharedCount++
+			}
+		}
+		assert.Equal(t, n-1, sharedCount)
+	})
+
+	t.Run("retries the shared call until it succeeds", func(t *testing.T) {
+		var g Group
+		var count int32
+
+		retryer := Retryer{Floor: time.Millisecond, Ceil: time.Millisecond * 5}
+		val, shared, err := g.Do(context.Background(), "key", retryer, func(ctx context.Context) (any, error) {
+			if atomic.AddInt32(&count, 1) < 3 {
+				return nil, io.EOF
+			}
+			return "done", nil
+		})
+		require.NoError(t, err)
+		assert.False(t, shared)
+		assert.Equal(t, "done", val)
+		assert.EqualValues(t, 3, atomic.LoadInt32(&count))
+	})
+
+	t.Run("a fresh caller doesn't inherit a torn-down call's cancellation", func(t *testing.T) {
+		var g Group
+		retryer := Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+
+		abandoned, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		go func() {
+			_, _, _ = g.Do(abandoned, "key", retryer, func(ctx context.Context) (any, error) {
+				close(started)
+				<-release
+				return nil, io.EOF
+			})
+		}()
+
+		<-started
+		cancel() // the only waiter leaves; the in-flight call gets torn down
+		time.Sleep(time.Millisecond * 10)
+
+		val, shared, err := g.Do(context.Background(), "key", retryer, func(ctx context.Context) (any, error) {
+			return "fresh", nil
+		})
+		close(release)
+
+		require.NoError(t, err)
+		assert.False(t, shared)
+		assert.Equal(t, "fresh", val)
+	})
+}
@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryerHooks(t *testing.T) {
+	t.Run("Run reports each attempt and the final success", func(t *testing.T) {
+		var attempts []int
+		var succeeded bool
+
+		r := &Retryer{
+			Floor: time.Millisecond,
+			Ceil:  time.Millisecond,
+			OnAttempt: func(attempt int, err error, nextDelay time.Duration) {
+				attempts = append(attempts, attempt)
+				assert.ErrorIs(t, err, io.EOF)
+			},
+			OnSuccess: func(attempt int, totalElapsed time.Duration) {
+				succeeded = true
+				assert.Equal(t, 3, attempt)
+			},
+		}
+
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			if count == 3 {
+				return nil
+			}
+			return io.EOF
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, attempts)
+		assert.True(t, succeeded)
+	})
+
+	t.Run("Run reports OnGiveUp when a permanent error stops the loop", func(t *testing.T) {
+		var gaveUp error
+
+		r := &Retryer{
+			Floor:    time.Millisecond,
+			Ceil:     time.Millisecond,
+			OnGiveUp: func(err error, totalElapsed time.Duration) { gaveUp = err },
+		}
+
+		err := r.Run(context.Background(), func() error {
+			return Permanent(io.ErrUnexpectedEOF)
+		})
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+		assert.Equal(t, io.ErrUnexpectedEOF, gaveUp)
+	})
+
+	t.Run("Reconnect reports OnGiveUp when ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond*20, cancel)
+
+		var gaveUp error
+		r := &Retryer{
+			Floor:    time.Millisecond,
+			Ceil:     time.Millisecond,
+			OnGiveUp: func(err error, totalElapsed time.Duration) { gaveUp = err },
+		}
+
+		err := r.Reconnect(ctx, func(ctx context.Context) error {
+			return io.EOF
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, gaveUp, context.Canceled)
+	})
+}
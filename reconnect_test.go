@@ -0,0 +1,88 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryerReconnect(t *testing.T) {
+	t.Run("resets the delay after a healthy connection", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var delays []time.Duration
+		r := &Retryer{
+			Floor:             time.Millisecond,
+			Ceil:              time.Second,
+			GraceAfterSuccess: time.Millisecond * 20,
+			OnAttempt: func(attempt int, err error, nextDelay time.Duration) {
+				delays = append(delays, nextDelay)
+			},
+		}
+
+		var count int
+		err := r.Reconnect(ctx, func(ctx context.Context) error {
+			count++
+			if count == 1 {
+				// Unhealthy: drops immediately, so the delay should grow.
+				return io.EOF
+			}
+			if count == 2 {
+				// Healthy: outlives the grace period, so the delay
+				// should reset to Floor afterwards.
+				time.Sleep(time.Millisecond * 25)
+				return io.EOF
+			}
+			cancel()
+			return io.EOF
+		})
+		assert.Equal(t, 3, count)
+		assert.ErrorIs(t, err, context.Canceled)
+		// Without the reset the second delay would have doubled to 2ms.
+		assert.Equal(t, []time.Duration{time.Millisecond, time.Millisecond}, delays)
+	})
+
+	t.Run("gives up on a permanent error", func(t *testing.T) {
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		var count int
+		err := r.Reconnect(context.Background(), func(ctx context.Context) error {
+			count++
+			return Permanent(io.ErrUnexpectedEOF)
+		})
+		assert.Equal(t, 1, count)
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+
+	t.Run("stops when ctx is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond*50, cancel)
+
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		err := r.Reconnect(ctx, func(ctx context.Context) error {
+			return io.EOF
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("does not call OnAttempt for a clean disconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond*50, cancel)
+
+		var onAttemptCalls int
+		r := &Retryer{
+			Floor:     time.Millisecond,
+			Ceil:      time.Millisecond,
+			OnAttempt: func(attempt int, err error, nextDelay time.Duration) { onAttemptCalls++ },
+		}
+		err := r.Reconnect(ctx, func(ctx context.Context) error {
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, onAttemptCalls)
+	})
+}
@@ -0,0 +1,138 @@
+// Package retry provides helpers for retrying fallible operations with
+// fixed intervals, deadlines, or exponential backoff.
+package retry
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Attempts calls fn up to n times, sleeping delay after each failed call
+// (including the last one), until fn returns a nil error. If fn never succeeds, it returns a multi-error
+// aggregating every failed attempt (see AttemptErrors), rather than only
+// the last one. A Permanent error, or one rejected by defaultIsRetryable,
+// ends the attempts early and is returned as-is.
+func Attempts(n int, delay time.Duration, fn func() error) error {
+	start := time.Now()
+	var errs []error
+	for i := 0; i < n; i++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if give, stop := classify(err, nil); stop {
+			return give
+		}
+		errs = append(errs, &AttemptError{Attempt: i + 1, Elapsed: time.Since(start), Err: err})
+		time.Sleep(delay)
+	}
+	return aggregateErrors(errs)
+}
+
+// Timeout calls fn repeatedly, sleeping delay between each call, until fn
+// returns a nil error or timeout has elapsed since Timeout was called. If
+// fn never succeeds, it returns a multi-error aggregating every failed
+// attempt (see AttemptErrors), rather than only the last one. A Permanent
+// error, or one rejected by defaultIsRetryable, ends the attempts early
+// and is returned as-is.
+func Timeout(timeout time.Duration, delay time.Duration, fn func() error) error {
+	start := time.Now()
+	var errs []error
+	for attempt := 1; time.Since(start) < timeout; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if give, stop := classify(err, nil); stop {
+			return give
+		}
+		errs = append(errs, &AttemptError{Attempt: attempt, Elapsed: time.Since(start), Err: err})
+		time.Sleep(delay)
+	}
+	return aggregateErrors(errs)
+}
+
+// Backoff calls fn repeatedly until it returns a nil error or deadline has
+// elapsed since Backoff was called. A deadline of zero means there is no
+// deadline. Between calls it sleeps for an exponentially increasing delay,
+// starting at floor and never exceeding ceil. If fn never succeeds, it
+// returns a multi-error aggregating every failed attempt (see
+// AttemptErrors), rather than only the last one. A Permanent error, or one
+// rejected by defaultIsRetryable, ends the attempts early and is returned
+// as-is.
+func Backoff(deadline, ceil, floor time.Duration, fn func() error) error {
+	start := time.Now()
+	delay := floor
+	var errs []error
+	for attempt := 1; deadline == 0 || time.Since(start) < deadline; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if give, stop := classify(err, nil); stop {
+			return give
+		}
+		errs = append(errs, &AttemptError{Attempt: attempt, Elapsed: time.Since(start), Err: err})
+		time.Sleep(delay)
+		delay *= 2
+		if delay > ceil {
+			delay = ceil
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// BackoffContext is like Backoff, but uses ctx's cancellation instead of a
+// deadline. If ctx is done before fn succeeds, it returns a multi-error
+// aggregating every failed attempt (see AttemptErrors), or ctx.Err() if
+// ctx was already done before the first attempt ran. A Permanent error, or
+// one rejected by defaultIsRetryable, ends the attempts early and is
+// returned as-is.
+//
+// BackoffContext is a thin wrapper around Retryer; call Retryer.Run
+// directly for jitter, a custom IsRetryable, or observability hooks.
+func BackoffContext(ctx context.Context, ceil, floor time.Duration, fn func() error) error {
+	r := &Retryer{Floor: floor, Ceil: ceil}
+	return r.Run(ctx, fn)
+}
+
+// Listener wraps a net.Listener, retrying Accept when it returns a
+// temporary net.Error (as determined by the Temporary method) instead of
+// giving up. LogTmpErr, if non-nil, is called with each temporary error
+// before retrying.
+type Listener struct {
+	net.Listener
+	LogTmpErr func(err error)
+}
+
+// listenerRetryable reports whether err is a net.Error that reports itself
+// as temporary; anything else (including a non-net.Error) is treated as
+// permanent, matching what Accept always did before it grew a Retryer.
+func listenerRetryable(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Temporary()
+}
+
+// Accept implements net.Listener.
+func (l *Listener) Accept() (net.Conn, error) {
+	r := &Retryer{
+		IsRetryable: listenerRetryable,
+		OnAttempt: func(attempt int, err error, nextDelay time.Duration) {
+			if l.LogTmpErr != nil {
+				l.LogTmpErr(err)
+			}
+		},
+	}
+
+	var conn net.Conn
+	err := r.Run(context.Background(), func() error {
+		var err error
+		conn, err = l.Listener.Accept()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
@@ -34,6 +34,16 @@ func TestAttempts(t *testing.T) {
 		})
 		assert.WithinDuration(t, time.Now(), start, time.Millisecond)
 	})
+
+	t.Run("gives up immediately on a permanent error", func(t *testing.T) {
+		count := 0
+		err := Attempts(100, time.Minute, func() error {
+			count++
+			return Permanent(io.ErrUnexpectedEOF)
+		})
+		assert.Equal(t, 1, count)
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
 }
 
 func TestTimeout(t *testing.T) {
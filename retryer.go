@@ -0,0 +1,243 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Jitter selects how Retryer spreads out the delay between attempts, so
+// that many callers retrying against the same backend don't stay in phase
+// with each other and hammer it in lockstep.
+type Jitter int
+
+const (
+	// JitterNone applies no jitter: delay grows as a pure exponential
+	// backoff from Floor to Ceil. This is the same behavior as Backoff
+	// and BackoffContext.
+	JitterNone Jitter = iota
+	// JitterFull picks each delay uniformly from [0, min(Ceil, Floor*2^(attempt-1))),
+	// where attempt counts from 1; that upper bound is the delay JitterNone
+	// would use.
+	// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+	JitterFull
+	// JitterDecorrelated derives each delay from the previous one:
+	// min(Ceil, Floor+rand(0, prev*3-Floor)). It spreads retries out more
+	// evenly over time than JitterFull, at the cost of being less tightly
+	// bounded on any single attempt.
+	JitterDecorrelated
+)
+
+// Retryer is a configurable retry loop. Unlike Backoff and BackoffContext,
+// it can jitter the delay between attempts, which matters when many
+// processes are retrying against the same failing backend at once.
+//
+// The zero value retries immediately with no delay between attempts,
+// which is only useful for something like Listener.Accept; most callers
+// should set at least Floor and Ceil.
+type Retryer struct {
+	// Floor is the starting delay of the exponential backoff (the delay
+	// before the first retry when Jitter is JitterNone), and the minimum
+	// delay used by JitterDecorrelated.
+	Floor time.Duration
+	// Ceil is the maximum delay between attempts.
+	Ceil time.Duration
+	// Jitter selects how the delay is randomized. The zero value,
+	// JitterNone, disables jitter.
+	Jitter Jitter
+
+	// Rand supplies randomness for Jitter. If nil, a package-level
+	// source is used. Inject a seeded *rand.Rand for deterministic
+	// tests.
+	Rand *rand.Rand
+
+	// IsRetryable, if non-nil, is consulted after each failed attempt.
+	// If it returns false, Run gives up immediately and returns that
+	// error instead of sleeping and trying again. If nil,
+	// defaultIsRetryable is used. Wrapping an error with Permanent
+	// always gives up, regardless of IsRetryable.
+	IsRetryable func(err error) bool
+
+	// GraceAfterSuccess is how long an attempt started by Reconnect must
+	// run before Reconnect considers it to have succeeded and resets the
+	// delay back to Floor. It has no effect on Run. Zero disables the
+	// reset: the delay keeps growing no matter how long each attempt
+	// lasted.
+	GraceAfterSuccess time.Duration
+
+	// OnAttempt, if non-nil, is called after each failed attempt that
+	// Run or Reconnect is about to retry, just before it sleeps for
+	// nextDelay.
+	OnAttempt func(attempt int, err error, nextDelay time.Duration)
+	// OnGiveUp, if non-nil, is called when Run or Reconnect gives up and
+	// returns a non-nil error: ctx ran out, err was wrapped with
+	// Permanent, or IsRetryable rejected it.
+	OnGiveUp func(err error, totalElapsed time.Duration)
+	// OnSuccess, if non-nil, is called when fn finally returns a nil
+	// error.
+	OnSuccess func(attempt int, totalElapsed time.Duration)
+}
+
+// Run calls fn until it returns a nil error, ctx is done, or fn's error is
+// classified as non-retryable. Between attempts it sleeps for a delay that
+// grows exponentially from Floor to Ceil, randomized according to Jitter.
+//
+// If ctx runs out before fn succeeds, Run returns a multi-error aggregating
+// every failed attempt (see AttemptErrors), or ctx.Err() if ctx was already
+// done before fn got to run at all. A Permanent error or one rejected by
+// IsRetryable is returned as-is, without aggregation, since in that case
+// there is no ambiguity about why the loop gave up.
+func (r *Retryer) Run(ctx context.Context, fn func() error) error {
+	start := time.Now()
+	var errs []error
+	expDelay := r.Floor
+	prevSleep := r.Floor
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return r.giveUp(aggregateOrDone(ctx, errs), start)
+		default:
+		}
+		err := fn()
+		if err == nil {
+			if r.OnSuccess != nil {
+				r.OnSuccess(attempt, time.Since(start))
+			}
+			return nil
+		}
+
+		if give, stop := classify(err, r.IsRetryable); stop {
+			return r.giveUp(give, start)
+		}
+
+		errs = append(errs, &AttemptError{Attempt: attempt, Elapsed: time.Since(start), Err: err})
+
+		var wait time.Duration
+		wait, prevSleep = r.jitteredDelay(expDelay, prevSleep)
+
+		if r.OnAttempt != nil {
+			r.OnAttempt(attempt, err, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return r.giveUp(aggregateOrDone(ctx, errs), start)
+		case <-time.After(wait):
+		}
+
+		expDelay *= 2
+		if expDelay > r.Ceil {
+			expDelay = r.Ceil
+		}
+	}
+}
+
+// Reconnect calls fn in a loop until ctx is done. Each call represents one
+// connection attempt: fn should establish the connection, then block for
+// as long as it stays up, returning nil for a clean disconnect or the
+// error that broke it. Reconnect always tries again after fn returns,
+// unless the error is a Permanent one or rejected by IsRetryable, in which
+// case it gives up and returns that error.
+//
+// The delay before the next attempt grows exponentially from Floor to
+// Ceil, as in Run. But if an attempt ran for at least GraceAfterSuccess
+// before fn returned, Reconnect treats it as healthy and resets the delay
+// back to Floor, so a long-lived connection that eventually drops
+// reconnects quickly instead of picking up where the backoff left off.
+func (r *Retryer) Reconnect(ctx context.Context, fn func(ctx context.Context) error) error {
+	loopStart := time.Now()
+	expDelay := r.Floor
+	prevSleep := r.Floor
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return r.giveUp(ctx.Err(), loopStart)
+		default:
+		}
+
+		start := time.Now()
+		err := fn(ctx)
+		if ctx.Err() != nil {
+			return r.giveUp(ctx.Err(), loopStart)
+		}
+
+		if err != nil {
+			if give, stop := classify(err, r.IsRetryable); stop {
+				return r.giveUp(give, loopStart)
+			}
+		}
+
+		if r.GraceAfterSuccess > 0 && time.Since(start) >= r.GraceAfterSuccess {
+			expDelay = r.Floor
+			prevSleep = r.Floor
+		}
+
+		var wait time.Duration
+		wait, prevSleep = r.jitteredDelay(expDelay, prevSleep)
+
+		if err != nil && r.OnAttempt != nil {
+			r.OnAttempt(attempt, err, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return r.giveUp(ctx.Err(), loopStart)
+		case <-time.After(wait):
+		}
+
+		expDelay *= 2
+		if expDelay > r.Ceil {
+			expDelay = r.Ceil
+		}
+	}
+}
+
+// giveUp reports err via OnGiveUp, if set and err is non-nil, and returns
+// err unchanged.
+func (r *Retryer) giveUp(err error, start time.Time) error {
+	if err != nil && r.OnGiveUp != nil {
+		r.OnGiveUp(err, time.Since(start))
+	}
+	return err
+}
+
+// jitteredDelay computes the delay to sleep before the next attempt from
+// the current exponential delay, applying Jitter. It returns the delay to
+// use now and the prevSleep to pass in next time.
+func (r *Retryer) jitteredDelay(expDelay, prevSleep time.Duration) (wait, nextPrevSleep time.Duration) {
+	switch r.Jitter {
+	case JitterFull:
+		return time.Duration(r.randFloat64() * float64(expDelay)), prevSleep
+	case JitterDecorrelated:
+		spread := prevSleep*3 - r.Floor
+		d := r.Floor
+		if spread > 0 {
+			d = r.Floor + time.Duration(r.randInt63n(int64(spread)))
+		}
+		if d > r.Ceil {
+			d = r.Ceil
+		}
+		return d, d
+	default:
+		return expDelay, prevSleep
+	}
+}
+
+// randFloat64 and randInt63n draw jitter from r.Rand if set. Otherwise
+// they fall back to the top-level math/rand functions, which (unlike a
+// bare *rand.Rand) are safe to call concurrently; that matters here
+// because many Retryers with no Rand set typically run at once, which is
+// the exact scenario Jitter exists for.
+func (r *Retryer) randFloat64() float64 {
+	if r.Rand != nil {
+		return r.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+func (r *Retryer) randInt63n(n int64) int64 {
+	if r.Rand != nil {
+		return r.Rand.Int63n(n)
+	}
+	return rand.Int63n(n)
+}
@@ -0,0 +1,126 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryer(t *testing.T) {
+	t.Run("return when nil", func(t *testing.T) {
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Second}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			if count == 10 {
+				return nil
+			}
+			return io.EOF
+		})
+		assert.Equal(t, 10, count)
+		assert.NoError(t, err)
+	})
+
+	t.Run("respects ctx cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond*100, cancel)
+		defer cancel()
+
+		r := &Retryer{Floor: time.Millisecond * 5, Ceil: time.Millisecond * 5}
+		start := time.Now()
+		err := r.Run(ctx, func() error {
+			return io.EOF
+		})
+		require.Error(t, err)
+		assert.WithinDuration(t, start.Add(time.Millisecond*100), time.Now(), time.Millisecond*10)
+	})
+
+	t.Run("full jitter never exceeds the exponential delay", func(t *testing.T) {
+		r := &Retryer{
+			Floor:  time.Millisecond,
+			Ceil:   time.Millisecond * 50,
+			Jitter: JitterFull,
+			Rand:   rand.New(rand.NewSource(1)),
+		}
+		r.OnAttempt = func(attempt int, err error, nextDelay time.Duration) {
+			bound := r.Floor << (attempt - 1)
+			if bound > r.Ceil {
+				bound = r.Ceil
+			}
+			assert.Less(t, nextDelay, bound)
+		}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			if count == 6 {
+				return nil
+			}
+			return io.EOF
+		})
+		assert.Equal(t, 6, count)
+		assert.NoError(t, err)
+	})
+
+	t.Run("decorrelated jitter stays within floor and ceil", func(t *testing.T) {
+		r := &Retryer{
+			Floor:  time.Millisecond,
+			Ceil:   time.Millisecond * 20,
+			Jitter: JitterDecorrelated,
+			Rand:   rand.New(rand.NewSource(2)),
+		}
+		r.OnAttempt = func(attempt int, err error, nextDelay time.Duration) {
+			assert.GreaterOrEqual(t, nextDelay, r.Floor)
+			assert.LessOrEqual(t, nextDelay, r.Ceil)
+		}
+		var count int
+		err := r.Run(context.Background(), func() error {
+			count++
+			if count == 6 {
+				return nil
+			}
+			return io.EOF
+		})
+		assert.Equal(t, 6, count)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports ctx.Err when ctx is already done before the first attempt", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond}
+		var called bool
+		err := r.Run(ctx, func() error {
+			called = true
+			return nil
+		})
+		assert.False(t, called)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("full jitter with no Rand set is safe for concurrent Retryers", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r := &Retryer{Floor: time.Millisecond, Ceil: time.Millisecond * 5, Jitter: JitterFull}
+				var count int
+				_ = r.Run(context.Background(), func() error {
+					count++
+					if count == 3 {
+						return nil
+					}
+					return io.EOF
+				})
+			}()
+		}
+		wg.Wait()
+	})
+}